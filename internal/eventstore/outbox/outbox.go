@@ -0,0 +1,60 @@
+// Package outbox implements the transactional outbox pattern for the
+// eventstore: side effects that need to run for a committed event (webhooks,
+// notifications, projection fan-out) are enqueued atomically with the event
+// itself and executed out-of-band by a Worker, instead of running inside the
+// same transaction as Push or relying on a separate poller.
+package outbox
+
+import (
+	"context"
+	"sync"
+
+	"github.com/zitadel/zitadel/internal/eventstore"
+)
+
+// EventRef identifies the event an outbox row was written for. It is a
+// pointer rather than a copy of the event's full payload: handlers that need
+// more than the type/position/sequence re-read the event itself via the
+// eventstore's existing query API, so the outbox row stays small and we
+// never need a second, independent way to deserialize an eventstore.Event.
+type EventRef struct {
+	Type     eventstore.EventType `json:"type"`
+	Position float64              `json:"position"`
+	Sequence uint32               `json:"sequence"`
+}
+
+// Handler processes a single outbox entry. Returning an error marks the
+// entry for retry (or the dead-letter table once retries are exhausted).
+type Handler func(ctx context.Context, ref EventRef) error
+
+var (
+	handlersMu sync.RWMutex
+	handlers   = map[eventstore.EventType]Handler{}
+)
+
+// RegisterOutboxHandler registers fn to be invoked out-of-band for every
+// committed event of eventType. Registering the same eventType twice
+// overwrites the previous handler. Handlers must be registered before the
+// eventstore is used, typically during setup/command-side wiring.
+func RegisterOutboxHandler(eventType eventstore.EventType, fn Handler) {
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+	handlers[eventType] = fn
+}
+
+// IsConfigured reports whether eventType has a registered handler and must
+// therefore be written to the outbox table in the same transaction as the
+// event itself.
+func IsConfigured(eventType eventstore.EventType) bool {
+	handlersMu.RLock()
+	defer handlersMu.RUnlock()
+	_, ok := handlers[eventType]
+	return ok
+}
+
+func handlerFor(eventType eventstore.EventType) (Handler, bool) {
+	handlersMu.RLock()
+	defer handlersMu.RUnlock()
+	h, ok := handlers[eventType]
+	return h, ok
+}
@@ -0,0 +1,212 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"time"
+
+	"github.com/zitadel/logging"
+
+	"github.com/zitadel/zitadel/internal/eventstore"
+)
+
+//go:embed claim.sql
+var claimStmt string
+
+//go:embed mark_done.sql
+var markDoneStmt string
+
+//go:embed mark_failed.sql
+var markFailedStmt string
+
+//go:embed dead_letter.sql
+var deadLetterStmt string
+
+const (
+	defaultBatchSize    = 100
+	defaultMaxAttempts  = 5
+	defaultBackoff      = 2 * time.Second
+	defaultLeaseSeconds = 30
+)
+
+// Worker periodically claims pending outbox rows and dispatches them to the
+// handler registered for their event type. claim.sql selects and marks rows
+// claimed in a single statement (a CTE doing SELECT ... FOR UPDATE SKIP
+// LOCKED feeding an UPDATE ... RETURNING), so the claim is durable before
+// the statement returns; multiple Worker instances can therefore run
+// concurrently without dispatching the same row twice. A row whose claim is
+// older than the lease is treated as abandoned (its Worker presumably died
+// mid-dispatch) and becomes claimable again.
+type Worker struct {
+	client       *sql.DB
+	interval     time.Duration
+	batchSize    int
+	maxAttempts  int
+	backoff      time.Duration
+	leaseSeconds int
+}
+
+// WorkerOption configures optional Worker behaviour.
+type WorkerOption func(*Worker)
+
+// WithBatchSize overrides the number of rows claimed per poll.
+func WithBatchSize(n int) WorkerOption {
+	return func(w *Worker) { w.batchSize = n }
+}
+
+// WithMaxAttempts overrides how many times in total a row's handler is
+// attempted before the row is moved to the dead-letter table.
+func WithMaxAttempts(n int) WorkerOption {
+	return func(w *Worker) { w.maxAttempts = n }
+}
+
+// WithLease overrides how long a claim is honoured before the row is
+// considered abandoned and reclaimable by another Worker.
+func WithLease(d time.Duration) WorkerOption {
+	return func(w *Worker) { w.leaseSeconds = int(d.Seconds()) }
+}
+
+// NewWorker creates a Worker that polls client every interval for pending
+// outbox rows.
+func NewWorker(client *sql.DB, interval time.Duration, opts ...WorkerOption) *Worker {
+	w := &Worker{
+		client:       client,
+		interval:     interval,
+		batchSize:    defaultBatchSize,
+		maxAttempts:  defaultMaxAttempts,
+		backoff:      defaultBackoff,
+		leaseSeconds: defaultLeaseSeconds,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Start polls for pending outbox rows until ctx is cancelled.
+func (w *Worker) Start(ctx context.Context) {
+	t := time.NewTicker(w.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := w.dispatchPending(ctx); err != nil {
+				logging.WithError(err).Warn("outbox: failed to dispatch pending rows")
+			}
+		}
+	}
+}
+
+type outboxRow struct {
+	id        string
+	eventType eventstore.EventType
+	payload   []byte
+	attempts  int
+}
+
+// dispatchPending claims a batch of rows, invokes the handler registered for
+// each row's event type, and marks every row done, retried, or dead-lettered
+// depending on the outcome.
+func (w *Worker) dispatchPending(ctx context.Context) error {
+	rows, err := w.claim(ctx)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	for _, row := range rows {
+		w.dispatchOne(ctx, row)
+	}
+	return nil
+}
+
+// claim runs claimStmt, which selects and marks pending rows as claimed in a
+// single atomic statement, so a row is durably claimed before it is ever
+// returned to a caller — unlike running the SELECT ... FOR UPDATE SKIP
+// LOCKED and the claim marker as two statements in a transaction that
+// commits (and releases the row locks) before dispatch.
+func (w *Worker) claim(ctx context.Context) ([]*outboxRow, error) {
+	sqlRows, err := w.client.QueryContext(ctx, claimStmt, w.batchSize, w.leaseSeconds)
+	if err != nil {
+		return nil, err
+	}
+	defer sqlRows.Close()
+
+	var rows []*outboxRow
+	for sqlRows.Next() {
+		row := new(outboxRow)
+		if err := sqlRows.Scan(&row.id, &row.eventType, &row.payload, &row.attempts); err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	if err := sqlRows.Err(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (w *Worker) dispatchOne(ctx context.Context, row *outboxRow) {
+	handler, ok := handlerFor(row.eventType)
+	if !ok {
+		// No handler registered anymore (e.g. removed after a deploy); drop
+		// the row rather than retrying forever.
+		w.markDone(ctx, row.id)
+		return
+	}
+
+	var ref EventRef
+	if err := json.Unmarshal(row.payload, &ref); err != nil {
+		logging.WithError(err).WithField("id", row.id).Warn("outbox: failed to decode event ref")
+		w.deadLetter(ctx, row, err)
+		return
+	}
+
+	if err := handler(ctx, ref); err != nil {
+		w.retryOrDeadLetter(ctx, row, err)
+		return
+	}
+	w.markDone(ctx, row.id)
+}
+
+func (w *Worker) markDone(ctx context.Context, id string) {
+	if _, err := w.client.ExecContext(ctx, markDoneStmt, id); err != nil {
+		logging.WithError(err).WithField("id", id).Warn("outbox: failed to mark row done")
+	}
+}
+
+func (w *Worker) retryOrDeadLetter(ctx context.Context, row *outboxRow, cause error) {
+	if exhausted(row.attempts, w.maxAttempts) {
+		w.deadLetter(ctx, row, cause)
+		return
+	}
+	nextAttemptAt := time.Now().Add(retryBackoff(w.backoff, row.attempts))
+	if _, err := w.client.ExecContext(ctx, markFailedStmt, row.id, cause.Error(), nextAttemptAt); err != nil {
+		logging.WithError(err).WithField("id", row.id).Warn("outbox: failed to record retry")
+	}
+}
+
+// exhausted reports whether a row that has already failed attempts times
+// should be moved to the dead-letter table instead of retried again.
+func exhausted(attempts, maxAttempts int) bool {
+	return attempts+1 >= maxAttempts
+}
+
+// retryBackoff returns the delay before the next attempt, growing linearly
+// with the number of attempts already made.
+func retryBackoff(base time.Duration, attempts int) time.Duration {
+	return base * time.Duration(attempts+1)
+}
+
+func (w *Worker) deadLetter(ctx context.Context, row *outboxRow, cause error) {
+	if _, err := w.client.ExecContext(ctx, deadLetterStmt, row.id, cause.Error()); err != nil {
+		logging.WithError(err).WithField("id", row.id).Warn("outbox: failed to move row to dead letter table")
+	}
+}
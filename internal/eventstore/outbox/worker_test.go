@@ -0,0 +1,45 @@
+package outbox
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExhausted(t *testing.T) {
+	tests := []struct {
+		name        string
+		attempts    int
+		maxAttempts int
+		want        bool
+	}{
+		{name: "first failure, room to retry", attempts: 0, maxAttempts: 5, want: false},
+		{name: "one below the limit", attempts: 3, maxAttempts: 5, want: false},
+		{name: "reaches the limit", attempts: 4, maxAttempts: 5, want: true},
+		{name: "already past the limit", attempts: 10, maxAttempts: 5, want: true},
+		{name: "zero max attempts dead-letters immediately", attempts: 0, maxAttempts: 0, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exhausted(tt.attempts, tt.maxAttempts); got != tt.want {
+				t.Errorf("exhausted(%d, %d) = %v, want %v", tt.attempts, tt.maxAttempts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	base := 2 * time.Second
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 0, want: 2 * time.Second},
+		{attempts: 1, want: 4 * time.Second},
+		{attempts: 4, want: 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := retryBackoff(base, tt.attempts); got != tt.want {
+			t.Errorf("retryBackoff(%v, %d) = %v, want %v", base, tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package eventstore
+
+import "github.com/zitadel/zitadel/internal/database"
+
+// Eventstore is the Postgres/CockroachDB backed implementation of
+// eventstore.Pusher and eventstore.Querier.
+type Eventstore struct {
+	client  *database.DB
+	options EventstoreOptions
+}
+
+// NewEventstore creates an Eventstore backed by client.
+func NewEventstore(client *database.DB, opts ...EventstoreOption) *Eventstore {
+	options := defaultEventstoreOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &Eventstore{client: client, options: options}
+}
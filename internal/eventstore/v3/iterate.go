@@ -0,0 +1,83 @@
+package eventstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zitadel/zitadel/internal/eventstore"
+	"github.com/zitadel/zitadel/internal/telemetry/tracing"
+)
+
+// ErrStopIteration can be returned from the fn passed to Iterate or
+// IterateBatch to halt iteration cleanly. Unlike any other error it is not
+// propagated to the caller.
+var ErrStopIteration = errors.New("eventstore: stop iteration")
+
+const defaultIterateBatchSize = 1000
+
+// Iterate streams every event matching filter to fn, one at a time, using a
+// keyset-paginated cursor over (position, in_tx_order) instead of
+// materializing the whole result set the way Query does. This keeps memory
+// bounded for projections rebuilding from scratch, which today have to page
+// manually via Limit and PositionAfter.
+//
+// fn returning an error aborts iteration and that error is returned from
+// Iterate, except for ErrStopIteration, which halts iteration without being
+// propagated.
+func (es *Eventstore) Iterate(ctx context.Context, filter *eventstore.SearchQueryBuilder, fn func(context.Context, eventstore.Event) error) error {
+	return es.IterateBatch(ctx, filter, defaultIterateBatchSize, func(ctx context.Context, events []eventstore.Event) error {
+		for _, event := range events {
+			if err := fn(ctx, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// IterateBatch is like Iterate but delivers up to batchSize events per call
+// to fn, for handlers that benefit from processing chunks rather than one
+// event at a time.
+func (es *Eventstore) IterateBatch(ctx context.Context, filter *eventstore.SearchQueryBuilder, batchSize int, fn func(context.Context, []eventstore.Event) error) (err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer func() { span.EndWithError(err) }()
+
+	cursor := filter
+	for {
+		batch, err := es.Query(ctx, cursor.Limit(uint32(batchSize)))
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := fn(ctx, batch); err != nil {
+			if stopIteration(err) {
+				return nil
+			}
+			return err
+		}
+
+		if !continueIteration(len(batch), batchSize) {
+			return nil
+		}
+
+		last := batch[len(batch)-1]
+		cursor = filter.PositionAfter(last.Position(), last.Sequence())
+	}
+}
+
+// continueIteration reports whether IterateBatch should fetch another batch
+// after fn processed one of length batchLen. A batch shorter than batchSize
+// means the query ran out of matching events, so there is nothing left to
+// page to.
+func continueIteration(batchLen, batchSize int) bool {
+	return batchLen == batchSize
+}
+
+// stopIteration reports whether an error returned by fn should end Iterate
+// cleanly, without being propagated to the caller.
+func stopIteration(err error) bool {
+	return errors.Is(err, ErrStopIteration)
+}
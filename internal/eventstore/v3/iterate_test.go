@@ -0,0 +1,47 @@
+package eventstore
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestContinueIteration(t *testing.T) {
+	tests := []struct {
+		name      string
+		batchLen  int
+		batchSize int
+		want      bool
+	}{
+		{name: "full batch keeps paging", batchLen: 100, batchSize: 100, want: true},
+		{name: "short batch is the last page", batchLen: 42, batchSize: 100, want: false},
+		{name: "empty batch size of one is still the last page", batchLen: 0, batchSize: 1, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := continueIteration(tt.batchLen, tt.batchSize); got != tt.want {
+				t.Errorf("continueIteration(%d, %d) = %v, want %v", tt.batchLen, tt.batchSize, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStopIteration(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil error", err: nil, want: false},
+		{name: "stop sentinel", err: ErrStopIteration, want: true},
+		{name: "wrapped stop sentinel", err: fmt.Errorf("handler: %w", ErrStopIteration), want: true},
+		{name: "unrelated error", err: errors.New("boom"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stopIteration(tt.err); got != tt.want {
+				t.Errorf("stopIteration(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
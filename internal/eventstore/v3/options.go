@@ -0,0 +1,46 @@
+package eventstore
+
+import (
+	"database/sql"
+	"time"
+)
+
+// EventstoreOptions configures behaviour of writeEvents that previously was
+// hardcoded: the transaction isolation level and the retry policy applied
+// when a push fails due to a transient, storage-level conflict.
+type EventstoreOptions struct {
+	// IsolationLevel is used for the transaction opened by writeEvents.
+	// Defaults to sql.LevelReadCommitted.
+	IsolationLevel sql.IsolationLevel
+	// MaxRetries is the number of additional attempts made after a push
+	// fails with a retryable error. 0 disables retrying.
+	MaxRetries int
+	// MinBackoff/MaxBackoff bound the exponential backoff (with jitter)
+	// applied between retries.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+func defaultEventstoreOptions() EventstoreOptions {
+	return EventstoreOptions{
+		IsolationLevel: sql.LevelReadCommitted,
+		MaxRetries:     3,
+		MinBackoff:     50 * time.Millisecond,
+		MaxBackoff:     2 * time.Second,
+	}
+}
+
+// EventstoreOption changes the behaviour of an Eventstore.
+type EventstoreOption func(*EventstoreOptions)
+
+// WithIsolationLevel overrides the transaction isolation level used when
+// writing events.
+func WithIsolationLevel(level sql.IsolationLevel) EventstoreOption {
+	return func(o *EventstoreOptions) { o.IsolationLevel = level }
+}
+
+// WithMaxRetries overrides how many times a push is retried after a
+// retryable storage error.
+func WithMaxRetries(n int) EventstoreOption {
+	return func(o *EventstoreOptions) { o.MaxRetries = n }
+}
@@ -0,0 +1,43 @@
+package eventstore
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+
+	"github.com/zitadel/zitadel/internal/eventstore"
+	"github.com/zitadel/zitadel/internal/eventstore/outbox"
+)
+
+//go:embed outbox_insert.sql
+var outboxInsertStmt string
+
+// writeOutboxRows enqueues every event of a type configured via
+// outbox.RegisterOutboxHandler in the eventstore.outbox table, inside the
+// same transaction as the events themselves. Enqueue is therefore atomic
+// with the commit: a handler either sees a row it is guaranteed to be able
+// to resolve, or the whole push rolled back and there is nothing to
+// dispatch. The row stores an outbox.EventRef rather than the event's full
+// payload; a handler that needs more than the type/position/sequence re-reads
+// the event from the eventstore itself.
+func writeOutboxRows(ctx context.Context, tx *sql.Tx, events []eventstore.Event) error {
+	for _, event := range events {
+		if !outbox.IsConfigured(event.Type()) {
+			continue
+		}
+		ref := outbox.EventRef{
+			Type:     event.Type(),
+			Position: event.Position(),
+			Sequence: event.Sequence(),
+		}
+		payload, err := json.Marshal(ref)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, outboxInsertStmt, event.Type(), payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
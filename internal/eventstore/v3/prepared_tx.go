@@ -0,0 +1,79 @@
+package eventstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/zitadel/zitadel/internal/eventstore"
+)
+
+// preparedShardTx is one shard's half of a two-phase commit across shards.
+// writeEvents runs against the shard's connection but the transaction is
+// prepared rather than committed, so the coordinator can decide to commit or
+// roll back every shard's transaction together.
+type preparedShardTx struct {
+	shardID   string
+	conn      *sql.Conn
+	gid       string
+	events    []eventstore.Event
+	committed bool
+}
+
+// preparedGID derives the Postgres global transaction ID PREPARE
+// TRANSACTION registers under. txnID must be unique per cross-shard push
+// (see sharded.go, which generates it from a uuid); combined with shardID it
+// gives every shard's half of the same push its own, collision-free name,
+// regardless of how many events either side writes.
+func preparedGID(txnID, shardID string) string {
+	return fmt.Sprintf("zitadel_shard_%s_%s", shardID, txnID)
+}
+
+func prepareShardTx(ctx context.Context, txnID string, group *shardGroup) (*preparedShardTx, error) {
+	conn, err := group.es.client.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{Isolation: group.es.options.IsolationLevel})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	events, err := writeEvents(ctx, tx, group.commands)
+	if err != nil {
+		_ = tx.Rollback()
+		conn.Close()
+		return nil, err
+	}
+
+	if err = handleUniqueConstraints(ctx, tx, group.commands); err != nil {
+		_ = tx.Rollback()
+		conn.Close()
+		return nil, err
+	}
+
+	gid := preparedGID(txnID, group.shardID)
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf("PREPARE TRANSACTION '%s'", gid)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &preparedShardTx{shardID: group.shardID, conn: conn, gid: gid, events: events}, nil
+}
+
+func (p *preparedShardTx) commit(ctx context.Context) ([]eventstore.Event, error) {
+	defer p.conn.Close()
+	if _, err := p.conn.ExecContext(ctx, fmt.Sprintf("COMMIT PREPARED '%s'", p.gid)); err != nil {
+		return nil, err
+	}
+	p.committed = true
+	return p.events, nil
+}
+
+func (p *preparedShardTx) rollback(ctx context.Context) error {
+	defer p.conn.Close()
+	_, err := p.conn.ExecContext(ctx, fmt.Sprintf("ROLLBACK PREPARED '%s'", p.gid))
+	return err
+}
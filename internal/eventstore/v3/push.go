@@ -4,7 +4,11 @@ import (
 	"context"
 	"database/sql"
 	_ "embed"
+	"errors"
+	"math/rand"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/zitadel/logging"
 
@@ -12,16 +16,70 @@ import (
 	"github.com/zitadel/zitadel/internal/telemetry/tracing"
 )
 
+// retryablePostgresCodes are SQLSTATE codes that indicate the transaction
+// failed only because it raced another one and can simply be retried from
+// the start: serialization_failure and deadlock_detected. CockroachDB reports
+// its retryable transaction errors as 40001 too, so the same entry covers it.
+//
+// A unique constraint violation (23505) is deliberately not in this set:
+// handleUniqueConstraints does not distinguish a concurrent push racing on
+// the same aggregate from a genuine business-rule conflict (e.g. a username
+// already taken), so retrying on 23505 here would silently retry requests
+// that are supposed to fail. Making that distinction requires classifying
+// handleUniqueConstraints' own constraint violations, which is out of scope
+// for this change.
+var retryablePostgresCodes = map[string]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+}
+
+// isRetryableError classifies an error returned by writeEvents as a
+// transient, storage-level conflict that is safe to retry as a whole
+// transaction: Postgres/CockroachDB serialization failures and deadlocks.
+func isRetryableError(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && retryablePostgresCodes[pgErr.Code]
+}
+
 func (es *Eventstore) Push(ctx context.Context, commands ...eventstore.Command) (events []eventstore.Event, err error) {
 	ctx, span := tracing.NewSpan(ctx)
 	defer func() { span.EndWithError(err) }()
 
-	events, err = es.writeEvents(ctx, commands)
-	if isSetupNotExecutedError(err) {
-		return es.pushWithoutFunc(ctx, commands...)
+	var retries int
+	var elapsed time.Duration
+	defer func() {
+		span.SetAttribute("push.retries", retries)
+		span.SetAttribute("push.retry_elapsed", elapsed.String())
+		if err != nil {
+			span.SetAttribute("push.last_error", err.Error())
+		}
+	}()
+
+	for {
+		start := time.Now()
+		events, err = es.writeEvents(ctx, commands)
+		elapsed += time.Since(start)
+		if isSetupNotExecutedError(err) {
+			return es.pushWithoutFunc(ctx, commands...)
+		}
+		if err == nil || !isRetryableError(err) || retries >= es.options.MaxRetries {
+			return events, err
+		}
+		logging.WithFields("retry", retries+1, "error", err).Info("retrying push after retryable storage error")
+		time.Sleep(backoff(es.options, retries))
+		retries++
 	}
+}
 
-	return events, err
+// backoff returns a random delay in [0, cap) for the given (zero-based) retry
+// attempt (full jitter), where cap is MinBackoff doubled once per attempt and
+// limited to MaxBackoff.
+func backoff(opts EventstoreOptions, attempt int) time.Duration {
+	max := opts.MinBackoff << attempt
+	if max <= 0 || max > opts.MaxBackoff {
+		max = opts.MaxBackoff
+	}
+	return time.Duration(rand.Int63n(int64(max)))
 }
 
 var (
@@ -42,7 +100,7 @@ func (es *Eventstore) writeEvents(ctx context.Context, commands []eventstore.Com
 	}
 
 	tx, err := conn.BeginTx(ctx, &sql.TxOptions{
-		Isolation: sql.LevelReadCommitted,
+		Isolation: es.options.IsolationLevel,
 		ReadOnly:  false,
 	})
 	if err != nil {
@@ -69,6 +127,10 @@ func (es *Eventstore) writeEvents(ctx context.Context, commands []eventstore.Com
 		return nil, err
 	}
 
+	if err = writeOutboxRows(ctx, tx, events); err != nil {
+		return nil, err
+	}
+
 	// CockroachDB by default does not allow multiple modifications of the same table using ON CONFLICT
 	// Thats why we enable it manually
 	if es.client.Type() == "cockroach" {
@@ -76,6 +138,15 @@ func (es *Eventstore) writeEvents(ctx context.Context, commands []eventstore.Com
 		if err != nil {
 			return nil, err
 		}
+	} else {
+		// pg_notify has no CockroachDB equivalent, so subscribers on a CRDB
+		// deployment are caught up by Subscribe's poll-fallback instead (see
+		// subscribe.go). Postgres delivers the notification only once this
+		// transaction actually commits, so a subscriber never wakes up for
+		// events it can't yet see.
+		if _, err = tx.ExecContext(ctx, "SELECT pg_notify($1, '')", notifyChannel); err != nil {
+			return nil, err
+		}
 	}
 
 	err = handleFieldCommands(ctx, tx, commands)
@@ -0,0 +1,75 @@
+package eventstore
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "nil error",
+			err:  nil,
+			want: false,
+		},
+		{
+			name: "serialization failure",
+			err:  &pgconn.PgError{Code: "40001"},
+			want: true,
+		},
+		{
+			name: "deadlock detected",
+			err:  &pgconn.PgError{Code: "40P01"},
+			want: true,
+		},
+		{
+			name: "wrapped serialization failure",
+			err:  fmt.Errorf("writeEvents: %w", &pgconn.PgError{Code: "40001"}),
+			want: true,
+		},
+		{
+			name: "unique violation is not retried",
+			err:  &pgconn.PgError{Code: "23505"},
+			want: false,
+		},
+		{
+			name: "wrapped unique violation is not retried",
+			err:  fmt.Errorf("handleUniqueConstraints: %w", &pgconn.PgError{Code: "23505"}),
+			want: false,
+		},
+		{
+			name: "unrelated error",
+			err:  errors.New("boom"),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableError(tt.err); got != tt.want {
+				t.Errorf("isRetryableError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	opts := EventstoreOptions{
+		MinBackoff: 10 * time.Millisecond,
+		MaxBackoff: 1 * time.Second,
+	}
+
+	for attempt := 0; attempt < 10; attempt++ {
+		d := backoff(opts, attempt)
+		if d < 0 || d > opts.MaxBackoff {
+			t.Errorf("backoff(opts, %d) = %v, want in [0, %v]", attempt, d, opts.MaxBackoff)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package eventstore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zitadel/logging"
+
+	"github.com/zitadel/zitadel/internal/eventstore"
+	"github.com/zitadel/zitadel/internal/telemetry/tracing"
+)
+
+// commitRetryOptions bounds the retries ShardedEventstore performs around
+// the COMMIT PREPARED step of a two-phase push. These are deliberately not
+// part of EventstoreOptions: a commit retry here only covers transient
+// connection errors talking to an already-prepared shard, not the
+// serialization-failure handling writeEvents' own retry loop deals with.
+var commitRetryOptions = EventstoreOptions{
+	MaxRetries: 3,
+	MinBackoff: 20 * time.Millisecond,
+	MaxBackoff: 500 * time.Millisecond,
+}
+
+// ShardPosition is the global position of an event once the eventstore is
+// sharded: the shard it was written to, plus that shard's own monotonic
+// position. Position/sequence stay monotonic per aggregate because every
+// aggregate lives on exactly one shard.
+type ShardPosition struct {
+	ShardID       string
+	LocalPosition float64
+}
+
+// ShardRouter resolves which shard an aggregate's events live on.
+type ShardRouter func(instanceID string, aggregateType eventstore.AggregateType, aggregateID string) string
+
+// ShardedEventstoreConfig describes the physical backends a ShardedEventstore
+// routes to and how aggregates map onto them.
+type ShardedEventstoreConfig struct {
+	// Shards maps a shard ID to the Eventstore backed by that shard's
+	// physical Postgres/CockroachDB instance.
+	Shards map[string]*Eventstore
+	// Route resolves the shard ID for a command's aggregate.
+	Route ShardRouter
+	// StronglyConsistent lists aggregate types that must use two-phase
+	// commit when a push spans more than one shard. Aggregate types not
+	// listed here fall back to best-effort per-shard commits with
+	// compensating events on partial failure.
+	StronglyConsistent map[eventstore.AggregateType]bool
+	// Compensate is invoked by pushBestEffort when a cross-shard push
+	// commits on some shards but not all of them. It is responsible for
+	// constructing and pushing whatever compensating event makes sense for
+	// the affected aggregates; ShardedEventstore has no domain knowledge to
+	// do that itself. If nil, pushBestEffort only reports the failure and
+	// leaves compensation entirely to the caller.
+	Compensate func(ctx context.Context, failure ShardPushFailure) error
+}
+
+// ShardPushFailure describes a cross-shard push that committed on some
+// shards but not others, for a registered Compensate handler to act on.
+type ShardPushFailure struct {
+	// Committed holds the events that did commit, across every shard that
+	// succeeded.
+	Committed []eventstore.Event
+	// FailedShards lists the shard IDs whose commands did not commit.
+	FailedShards []string
+	// FailedCommands lists the commands that were targeted at FailedShards.
+	FailedCommands []eventstore.Command
+}
+
+// ShardedEventstore routes Push to one of N physical backends keyed by
+// (instanceID, aggregateType, aggregateID), so a deployment can scale writes
+// horizontally past a single primary. A push that only touches aggregates on
+// one shard runs through that shard's existing single-transaction path
+// unchanged; a push spanning shards runs per-shard local transactions behind
+// a commit coordinator.
+type ShardedEventstore struct {
+	mu     sync.RWMutex
+	config ShardedEventstoreConfig
+}
+
+// NewShardedEventstore creates a ShardedEventstore from config.
+func NewShardedEventstore(config ShardedEventstoreConfig) *ShardedEventstore {
+	return &ShardedEventstore{config: config}
+}
+
+// Reconfigure swaps the shard map at runtime. Callers must have already
+// drained the source shard to read-only traffic before removing it from the
+// new config; in-flight pushes against the old map are allowed to finish.
+func (s *ShardedEventstore) Reconfigure(config ShardedEventstoreConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = config
+}
+
+type shardGroup struct {
+	shardID  string
+	es       *Eventstore
+	commands []eventstore.Command
+}
+
+func (s *ShardedEventstore) groupByShard(commands []eventstore.Command) ([]*shardGroup, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	byShard := make(map[string]*shardGroup)
+	var order []string
+	for _, cmd := range commands {
+		shardID := s.config.Route(cmd.Aggregate().InstanceID, cmd.Aggregate().Type, cmd.Aggregate().ID)
+		group, ok := byShard[shardID]
+		if !ok {
+			es, ok := s.config.Shards[shardID]
+			if !ok {
+				return nil, fmt.Errorf("eventstore: no shard registered for id %q", shardID)
+			}
+			group = &shardGroup{shardID: shardID, es: es}
+			byShard[shardID] = group
+			order = append(order, shardID)
+		}
+		group.commands = append(group.commands, cmd)
+	}
+
+	groups := make([]*shardGroup, len(order))
+	for i, id := range order {
+		groups[i] = byShard[id]
+	}
+	return groups, nil
+}
+
+func (s *ShardedEventstore) requiresStrongConsistency(commands []eventstore.Command) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, cmd := range commands {
+		if s.config.StronglyConsistent[cmd.Aggregate().Type] {
+			return true
+		}
+	}
+	return false
+}
+
+// Push writes commands, routing each aggregate to its shard. If every
+// command targets the same shard this is equivalent to a single call to
+// that shard's Push. Otherwise it commits per shard, coordinating across
+// shards as required by requiresStrongConsistency.
+func (s *ShardedEventstore) Push(ctx context.Context, commands ...eventstore.Command) (events []eventstore.Event, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer func() { span.EndWithError(err) }()
+
+	groups, err := s.groupByShard(commands)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(groups) == 1 {
+		return groups[0].es.Push(ctx, groups[0].commands...)
+	}
+
+	if s.requiresStrongConsistency(commands) {
+		return s.pushTwoPhase(ctx, groups)
+	}
+	return s.pushBestEffort(ctx, groups)
+}
+
+// pushTwoPhase uses Postgres PREPARE TRANSACTION on every shard touched by a
+// cross-shard push, and only commits the prepared transactions once all of
+// them have prepared successfully. If any shard fails to prepare, every
+// other shard's prepared transaction is rolled back instead of committed.
+//
+// The decision to commit is recorded durably on the coordinator shard (the
+// first shard in groups) before any COMMIT PREPARED is issued, and commits
+// are retried with backoff. If a commit still fails after retries, the
+// decision stays recorded as "committing" rather than being marked
+// resolved, so a later call to RecoverPreparedTransactions finishes the
+// commit on every shard instead of leaving it prepared (and its locks held)
+// forever.
+func (s *ShardedEventstore) pushTwoPhase(ctx context.Context, groups []*shardGroup) ([]eventstore.Event, error) {
+	txnID := uuid.NewString()
+	coordinator := groups[0].es
+	shardIDs := make([]string, len(groups))
+	for i, g := range groups {
+		shardIDs[i] = g.shardID
+	}
+
+	if err := recordDecision(ctx, coordinator, txnID, shardIDs, decisionPreparing); err != nil {
+		return nil, fmt.Errorf("eventstore: failed to record two-phase commit decision: %w", err)
+	}
+
+	prepared := make([]*preparedShardTx, 0, len(groups))
+	abort := func(cause error) ([]eventstore.Event, error) {
+		allRolledBack := true
+		for _, p := range prepared {
+			if rollbackErr := p.rollback(ctx); rollbackErr != nil {
+				logging.WithError(rollbackErr).WithField("shard", p.shardID).Warn("failed to roll back prepared shard transaction")
+				allRolledBack = false
+			}
+		}
+		// Only mark the decision resolved once every shard actually rolled
+		// back. A shard whose rollback failed still has a PREPARE
+		// TRANSACTION holding locks; leaving the decision unresolved lets
+		// RecoverPreparedTransactions retry the rollback on that shard
+		// instead of orphaning it forever.
+		if err := updateDecision(ctx, coordinator, txnID, decisionAborting, allRolledBack); err != nil {
+			logging.WithError(err).WithField("txn", txnID).Warn("failed to record abort decision")
+		}
+		return nil, cause
+	}
+
+	for _, group := range groups {
+		p, err := prepareShardTx(ctx, txnID, group)
+		if err != nil {
+			return abort(fmt.Errorf("eventstore: prepare on shard %q failed: %w", group.shardID, err))
+		}
+		prepared = append(prepared, p)
+	}
+
+	if err := updateDecision(ctx, coordinator, txnID, decisionCommitting, false); err != nil {
+		return abort(fmt.Errorf("eventstore: failed to record commit decision: %w", err))
+	}
+
+	var events []eventstore.Event
+	for _, p := range prepared {
+		committedEvents, err := commitWithRetry(ctx, p)
+		if err != nil {
+			// The decision is already durably "committing": a later call to
+			// RecoverPreparedTransactions will finish committing this and
+			// any later shard in the list, so we surface the error instead
+			// of retrying here forever, but we do not roll back shards that
+			// already committed.
+			return events, fmt.Errorf("eventstore: commit on shard %q failed after prepare and retries, will be completed by recovery: %w", p.shardID, err)
+		}
+		events = append(events, committedEvents...)
+	}
+
+	if err := updateDecision(ctx, coordinator, txnID, decisionCommitting, true); err != nil {
+		logging.WithError(err).WithField("txn", txnID).Warn("failed to mark two-phase commit resolved")
+	}
+	return events, nil
+}
+
+func commitWithRetry(ctx context.Context, p *preparedShardTx) ([]eventstore.Event, error) {
+	var events []eventstore.Event
+	var err error
+	for attempt := 0; ; attempt++ {
+		events, err = p.commit(ctx)
+		if err == nil || attempt >= commitRetryOptions.MaxRetries {
+			return events, err
+		}
+		time.Sleep(backoff(commitRetryOptions, attempt))
+	}
+}
+
+// pushBestEffort commits each shard independently. On partial failure the
+// shards that already committed are left as-is, and config.Compensate (if
+// registered) is invoked with the details needed to construct and push a
+// compensating event; ShardedEventstore itself has no domain knowledge to
+// build one.
+func (s *ShardedEventstore) pushBestEffort(ctx context.Context, groups []*shardGroup) ([]eventstore.Event, error) {
+	var events []eventstore.Event
+	var failedShards []string
+	var failedCommands []eventstore.Command
+	for _, group := range groups {
+		shardEvents, err := group.es.Push(ctx, group.commands...)
+		if err != nil {
+			logging.WithError(err).WithField("shard", group.shardID).Warn("best-effort shard push failed")
+			failedShards = append(failedShards, group.shardID)
+			failedCommands = append(failedCommands, group.commands...)
+			continue
+		}
+		events = append(events, shardEvents...)
+	}
+	if len(failedShards) == 0 {
+		return events, nil
+	}
+
+	failure := ShardPushFailure{
+		Committed:      events,
+		FailedShards:   failedShards,
+		FailedCommands: failedCommands,
+	}
+
+	s.mu.RLock()
+	compensate := s.config.Compensate
+	s.mu.RUnlock()
+
+	if compensate == nil {
+		return events, fmt.Errorf("eventstore: push failed on shards %v, succeeded shards' events were committed and require compensation", failedShards)
+	}
+	if err := compensate(ctx, failure); err != nil {
+		return events, fmt.Errorf("eventstore: push failed on shards %v and compensation failed: %w", failedShards, err)
+	}
+	return events, nil
+}
@@ -0,0 +1,219 @@
+package eventstore
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/zitadel/logging"
+
+	"github.com/zitadel/zitadel/internal/eventstore"
+	"github.com/zitadel/zitadel/internal/telemetry/tracing"
+)
+
+// pollInterval is how often a subscriber on a backend without LISTEN/NOTIFY
+// support (CockroachDB) re-checks for new events.
+const pollInterval = 500 * time.Millisecond
+
+// notifyChannel is the Postgres channel written to by writeEvents after a
+// successful commit and listened on by Subscribe.
+const notifyChannel = "zitadel_es_push"
+
+//go:embed subscribe_catchup.sql
+var subscribeCatchUpStmt string
+
+// GlobalPosition identifies a point in the eventstore's global ordering, the
+// same way eventstore.Event.Position/Sequence do.
+type GlobalPosition struct {
+	Position  float64
+	InTxOrder uint32
+}
+
+// SubscriptionEvent is sent on the channel returned by Subscribe. The first
+// message on a new subscription is always an Init message; every following
+// message carries the events found by one catch-up query after a wake-up.
+// Events of several transactions that committed between two wake-ups arrive
+// together in one message; messages are not split per transaction.
+type SubscriptionEvent struct {
+	// Init is non-nil only for the first message and reports the position
+	// the subscriber was caught up to when the subscription started.
+	Init *GlobalPosition
+	// Events holds the matching events found by one catch-up, in commit
+	// order.
+	Events []eventstore.Event
+	// Err is set if the subscription failed; the channel is closed right
+	// after. Callers should re-subscribe from the last-seen position.
+	Err error
+}
+
+// Subscribe streams events matching filter as they are committed, instead of
+// requiring callers to poll. It emits an Init message carrying the current
+// global position, then one message per catch-up that finds new events
+// matching filter (after each notification, or each poll interval on
+// backends without LISTEN/NOTIFY); one message can hold the events of more
+// than one transaction. If the subscription is interrupted the channel is closed
+// with a final Err message; callers resume by querying events after their
+// last-seen position and calling Subscribe again, so no events are lost
+// across reconnects.
+func (es *Eventstore) Subscribe(ctx context.Context, filter *eventstore.SearchQueryBuilder) (_ <-chan *SubscriptionEvent, err error) {
+	ctx, span := tracing.NewSpan(ctx)
+	defer func() { span.EndWithError(err) }()
+
+	conn, err := es.client.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// CockroachDB has no LISTEN/NOTIFY, so there is nothing to wait on;
+	// fall back to polling the existing query API on an interval instead.
+	// The poll loop always re-queries from lastSeen, so there is no
+	// ordering requirement against reading the current position below.
+	if es.client.Type() != "postgres" {
+		pos, err := currentPosition(ctx, conn)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+
+		out := make(chan *SubscriptionEvent, 16)
+		out <- &SubscriptionEvent{Init: pos}
+		go es.pollNotifications(ctx, conn, filter, *pos, out)
+		return out, nil
+	}
+
+	// LISTEN must be established before reading the current position: if
+	// the order were reversed, a transaction that commits (and fires
+	// pg_notify) between the position read and the LISTEN call would never
+	// wake this subscriber, since nothing is listening yet when it
+	// notifies. A notification arriving in the now-possible gap between
+	// LISTEN and reading the position is harmless, because catchUp always
+	// re-queries from lastSeen and is idempotent.
+	pgConn, err := listen(ctx, conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	pos, err := currentPosition(ctx, conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	out := make(chan *SubscriptionEvent, 16)
+	out <- &SubscriptionEvent{Init: pos}
+
+	go es.watchNotifications(ctx, conn, pgConn, filter, *pos, out)
+
+	return out, nil
+}
+
+// pollNotifications is the fallback used on backends without LISTEN/NOTIFY
+// support: it re-runs catchUp on a fixed interval instead of waiting for a
+// notification.
+func (es *Eventstore) pollNotifications(
+	ctx context.Context,
+	conn *sql.Conn,
+	filter *eventstore.SearchQueryBuilder,
+	lastSeen GlobalPosition,
+	out chan<- *SubscriptionEvent,
+) {
+	defer close(out)
+	defer conn.Close()
+
+	t := time.NewTicker(pollInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			out <- &SubscriptionEvent{Err: ctx.Err()}
+			return
+		case <-t.C:
+			events, newPos, err := catchUp(ctx, es, filter, lastSeen)
+			if err != nil {
+				logging.WithError(err).Warn("subscription catch up failed")
+				out <- &SubscriptionEvent{Err: err}
+				return
+			}
+			if len(events) == 0 {
+				continue
+			}
+			lastSeen = newPos
+			out <- &SubscriptionEvent{Events: events}
+		}
+	}
+}
+
+// listen issues LISTEN on the raw pgx connection backing conn so that
+// notifications sent by writeEvents via pg_notify wake this subscriber.
+func listen(ctx context.Context, conn *sql.Conn) (*pgx.Conn, error) {
+	var pgConn *pgx.Conn
+	err := conn.Raw(func(driverConn any) error {
+		pgConn = driverConn.(*stdlib.Conn).Conn()
+		_, err := pgConn.Exec(ctx, "LISTEN "+notifyChannel)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pgConn, nil
+}
+
+func (es *Eventstore) watchNotifications(
+	ctx context.Context,
+	conn *sql.Conn,
+	pgConn *pgx.Conn,
+	filter *eventstore.SearchQueryBuilder,
+	lastSeen GlobalPosition,
+	out chan<- *SubscriptionEvent,
+) {
+	defer close(out)
+	defer conn.Close()
+
+	for {
+		if _, err := pgConn.WaitForNotification(ctx); err != nil {
+			out <- &SubscriptionEvent{Err: err}
+			return
+		}
+
+		events, newPos, err := catchUp(ctx, es, filter, lastSeen)
+		if err != nil {
+			logging.WithError(err).Warn("subscription catch up failed")
+			out <- &SubscriptionEvent{Err: err}
+			return
+		}
+		if len(events) == 0 {
+			continue
+		}
+		lastSeen = newPos
+		out <- &SubscriptionEvent{Events: events}
+	}
+}
+
+// catchUp fetches every event after lastSeen matching filter using the
+// existing query API, so a subscriber that missed a notification (or just
+// reconnected) never misses events.
+func catchUp(ctx context.Context, es *Eventstore, filter *eventstore.SearchQueryBuilder, lastSeen GlobalPosition) ([]eventstore.Event, GlobalPosition, error) {
+	events, err := es.Query(ctx, filter.PositionAfter(lastSeen.Position, lastSeen.InTxOrder))
+	if err != nil {
+		return nil, lastSeen, err
+	}
+	if len(events) == 0 {
+		return nil, lastSeen, nil
+	}
+	last := events[len(events)-1]
+	return events, GlobalPosition{Position: last.Position(), InTxOrder: last.Sequence()}, nil
+}
+
+func currentPosition(ctx context.Context, conn *sql.Conn) (*GlobalPosition, error) {
+	row := conn.QueryRowContext(ctx, subscribeCatchUpStmt)
+	pos := new(GlobalPosition)
+	if err := row.Scan(&pos.Position, &pos.InTxOrder); err != nil {
+		return nil, err
+	}
+	return pos, nil
+}
@@ -0,0 +1,116 @@
+package eventstore
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"errors"
+	"strings"
+)
+
+//go:embed twophase_record.sql
+var twoPhaseRecordStmt string
+
+//go:embed twophase_update.sql
+var twoPhaseUpdateStmt string
+
+//go:embed twophase_pending.sql
+var twoPhasePendingStmt string
+
+// commitDecision is the durable record of what a cross-shard push decided to
+// do. Once a txnID's decision is "committing", every shard's prepared
+// transaction for that txnID WILL be committed, even across a coordinator
+// restart: RecoverPreparedTransactions replays that decision until every
+// shard confirms it.
+type commitDecision string
+
+const (
+	decisionPreparing  commitDecision = "preparing"
+	decisionCommitting commitDecision = "committing"
+	decisionAborting   commitDecision = "aborting"
+)
+
+// recordDecision durably records the coordinator's decision for txnID on the
+// coordinator shard (by convention the first shard touched by the push), so
+// a crash between preparing and committing every shard can be recovered
+// from instead of leaving prepared transactions holding locks forever.
+func recordDecision(ctx context.Context, coordinator *Eventstore, txnID string, shardIDs []string, decision commitDecision) error {
+	_, err := coordinator.client.ExecContext(ctx, twoPhaseRecordStmt, txnID, strings.Join(shardIDs, ","), string(decision))
+	return err
+}
+
+func updateDecision(ctx context.Context, coordinator *Eventstore, txnID string, decision commitDecision, resolved bool) error {
+	_, err := coordinator.client.ExecContext(ctx, twoPhaseUpdateStmt, txnID, string(decision), resolved)
+	return err
+}
+
+type pendingDecision struct {
+	txnID    string
+	shardIDs []string
+	decision commitDecision
+}
+
+// RecoverPreparedTransactions resolves every unresolved cross-shard push
+// recorded on coordinator: transactions still marked "committing" are
+// committed on every shard (a shard that already committed simply reports
+// the prepared transaction does not exist, which is treated as success),
+// and transactions marked "aborting" are rolled back the same way. Call this
+// on startup, and periodically, so a coordinator crash never leaves a
+// prepared transaction holding shard locks indefinitely.
+func RecoverPreparedTransactions(ctx context.Context, coordinator *Eventstore, shards map[string]*Eventstore) error {
+	rows, err := coordinator.client.QueryContext(ctx, twoPhasePendingStmt)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var pending []pendingDecision
+	for rows.Next() {
+		var p pendingDecision
+		var shardIDs string
+		var decision string
+		if err := rows.Scan(&p.txnID, &shardIDs, &decision); err != nil {
+			return err
+		}
+		p.shardIDs = strings.Split(shardIDs, ",")
+		p.decision = commitDecision(decision)
+		pending = append(pending, p)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, p := range pending {
+		if err := resolvePending(ctx, coordinator, shards, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func resolvePending(ctx context.Context, coordinator *Eventstore, shards map[string]*Eventstore, p pendingDecision) error {
+	for _, shardID := range p.shardIDs {
+		es, ok := shards[shardID]
+		if !ok {
+			return errors.New("eventstore: recovery references unknown shard " + shardID)
+		}
+		gid := preparedGID(p.txnID, shardID)
+		var stmt string
+		if p.decision == decisionCommitting {
+			stmt = "COMMIT PREPARED '" + gid + "'"
+		} else {
+			stmt = "ROLLBACK PREPARED '" + gid + "'"
+		}
+		if _, err := es.client.ExecContext(ctx, stmt); err != nil && !isUnknownPreparedTransaction(err) {
+			return err
+		}
+	}
+	return updateDecision(ctx, coordinator, p.txnID, p.decision, true)
+}
+
+// isUnknownPreparedTransaction reports whether err means the prepared
+// transaction named in the statement no longer exists, which during
+// recovery means it was already resolved by an earlier, successful attempt.
+func isUnknownPreparedTransaction(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "does not exist")
+}